@@ -0,0 +1,411 @@
+// Package cliopts declares command-line options by tagging a plain struct
+// instead of wiring up flag.String/flag.Int calls by hand. Usage:
+//
+//	type Options struct {
+//		Name  string `short:"n" long:"name" default:"world" description:"who to greet"`
+//		Count int    `short:"c" long:"count" default:"1" description:"number of greetings" required:"yes"`
+//	}
+//
+//	var opts Options
+//	if err := cliopts.Parse(&opts); err != nil {
+//		log.Fatal(err)
+//	}
+package cliopts
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jilpoom/go-tutorials/go-package/optstyle"
+)
+
+// FieldInfo is the tag metadata and reflected value for a single tagged
+// struct field, exported so other packages (ini, completion) can walk the
+// same option struct cliopts parses from the command line.
+type FieldInfo struct {
+	Short       string
+	Long        string
+	Default     string
+	Description string
+	Group       string
+	Required    bool
+	Value       reflect.Value
+}
+
+// field adds the bookkeeping ParseArgs needs on top of the public
+// FieldInfo.
+type field struct {
+	FieldInfo
+	set bool
+}
+
+// Parse reflects over v, which must be a pointer to a struct, registers each
+// tagged field as a short (-x) and/or long (--xxx) option, and fills it in
+// from os.Args[1:]. Fields without a "long" or "short" tag are ignored. Any
+// leftover positional arguments are discarded; use ParseArgs to keep them.
+func Parse(v interface{}) error {
+	_, err := ParseArgs(os.Args[1:], v)
+	return err
+}
+
+// ParseArgs behaves like Parse but parses args instead of os.Args[1:], and
+// returns the positional arguments left over (anything that isn't an option,
+// including everything after a "--" terminator).
+func ParseArgs(args []string, v interface{}) ([]string, error) {
+	state, err := NewState(v)
+	if err != nil {
+		return nil, err
+	}
+	return state.ParseArgs(args)
+}
+
+// State is v's tagged fields plus which of them have already been given an
+// explicit value, either by a previous State.Set call (e.g. from ini.Load)
+// or by a previous ParseArgs call on the same State. Parse and ParseArgs
+// build a throwaway State for one-shot use; callers that need to layer
+// multiple sources (an INI file, then the command line) should build one
+// explicitly with NewState and reuse it, so "is this field already set"
+// doesn't have to be guessed from whether its value happens to be zero.
+type State struct {
+	fields []*field
+}
+
+// NewState reflects over v, which must be a pointer to a struct, the same
+// way Fields does, and returns a State tracking which of its fields have
+// been explicitly set.
+func NewState(v interface{}) (*State, error) {
+	infos, err := Fields(v)
+	if err != nil {
+		return nil, err
+	}
+	return &State{fields: wrapFields(infos)}, nil
+}
+
+// Fields returns the tag metadata for s's fields, in struct declaration
+// order.
+func (s *State) Fields() []FieldInfo {
+	infos := make([]FieldInfo, len(s.fields))
+	for i, f := range s.fields {
+		infos[i] = f.FieldInfo
+	}
+	return infos
+}
+
+// Set coerces raw into the field tagged `long:"long"` and marks it as
+// explicitly set, so a later ParseArgs won't overwrite it with the tag
+// default even if raw happens to parse to the zero value.
+func (s *State) Set(long, raw string) error {
+	for _, f := range s.fields {
+		if f.Long == long {
+			if err := SetValue(f.FieldInfo, raw); err != nil {
+				return err
+			}
+			f.set = true
+			return nil
+		}
+	}
+	return fmt.Errorf("cliopts: unknown option --%s", long)
+}
+
+// ParseArgs parses args into s, applying tag defaults and checking
+// "required" only for fields that are still unset once parsing finishes.
+func (s *State) ParseArgs(args []string) ([]string, error) {
+	positional, _, err := parseInto(args, s.fields, false)
+	return positional, err
+}
+
+// ParseLeading parses only the leading run of option tokens in args into v
+// (applying tag defaults/required the same way ParseArgs does), stopping at
+// the first positional argument instead of erroring on it, and returns how
+// many of args were consumed. This is how a subcommand dispatcher should
+// split a global options struct's tokens from a command path that follows,
+// since it (unlike a plain "does this arg start with -" scan) knows from
+// the struct which options take a value and so won't mistake that value
+// for the start of the command path.
+func ParseLeading(args []string, v interface{}) (consumed int, err error) {
+	state, err := NewState(v)
+	if err != nil {
+		return 0, err
+	}
+	_, consumed, err = parseInto(args, state.fields, true)
+	return consumed, err
+}
+
+// Fields reflects over v, which must be a pointer to a struct, and returns
+// the tag metadata for every field carrying a "long" or "short" tag, in
+// struct declaration order.
+func Fields(v interface{}) ([]FieldInfo, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cliopts: expected a pointer to a struct, got %T", v)
+	}
+
+	t := rv.Elem().Type()
+	var fields []FieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		long := sf.Tag.Get("long")
+		short := sf.Tag.Get("short")
+		if long == "" && short == "" {
+			continue
+		}
+		fields = append(fields, FieldInfo{
+			Short:       short,
+			Long:        long,
+			Default:     sf.Tag.Get("default"),
+			Description: sf.Tag.Get("description"),
+			Group:       sf.Tag.Get("group"),
+			Required:    sf.Tag.Get("required") == "yes",
+			Value:       rv.Elem().Field(i),
+		})
+	}
+	return fields, nil
+}
+
+func wrapFields(infos []FieldInfo) []*field {
+	fields := make([]*field, len(infos))
+	for i, fi := range infos {
+		fields[i] = &field{FieldInfo: fi}
+	}
+	return fields
+}
+
+// parseInto parses tokenized args into fields. If stopAtPositional is true,
+// it stops as soon as it reaches the first positional argument (instead of
+// collecting it and continuing) and reports how many args came before it;
+// this is how ParseLeading finds the global-options/command-path boundary.
+func parseInto(args []string, fields []*field, stopAtPositional bool) (positional []string, consumed int, err error) {
+	byShort := map[string]*field{}
+	byLong := map[string]*field{}
+	for _, f := range fields {
+		if f.Short != "" {
+			byShort[f.Short] = f
+		}
+		if f.Long != "" {
+			byLong[f.Long] = f
+		}
+	}
+
+	tokens := optstyle.Tokenize(args)
+	i := 0
+	for ; i < len(tokens); i++ {
+		tok := tokens[i]
+
+		if tok.Kind == optstyle.Positional {
+			if stopAtPositional {
+				break
+			}
+			positional = append(positional, tok.Name)
+			continue
+		}
+		if (tok.Kind == optstyle.Long && tok.Name == "help") || (tok.Kind == optstyle.Short && tok.Name == "h") {
+			printHelp(fields)
+			os.Exit(0)
+		}
+
+		lookup := byLong
+		if tok.Kind == optstyle.Short {
+			lookup = byShort
+		}
+
+		f := lookup[tok.Name]
+		if f == nil && tok.Kind == optstyle.Short && len(tok.Name) > 1 {
+			// Bundled short options, e.g. "-xvf": every letter but the
+			// last must be boolean; the last takes its value from an
+			// attached "=value" or from the next argument. A value written
+			// directly after a letter ("-ofile") is not split off: each of
+			// its letters is read as an option.
+			bundle := []rune(tok.Name)
+			for j, r := range bundle {
+				bf := byShort[string(r)]
+				if bf == nil {
+					return nil, 0, fmt.Errorf("cliopts: unknown option -%c", r)
+				}
+				last := j == len(bundle)-1
+				if !last {
+					if bf.Value.Kind() != reflect.Bool {
+						return nil, 0, fmt.Errorf("cliopts: option -%c in bundle -%s requires a value and cannot be bundled", r, tok.Name)
+					}
+					bf.Value.SetBool(true)
+					bf.set = true
+					continue
+				}
+				if bf.Value.Kind() == reflect.Bool && !tok.HasValue {
+					bf.Value.SetBool(true)
+					bf.set = true
+					continue
+				}
+				value := tok.Value
+				if !tok.HasValue {
+					i++
+					if i >= len(tokens) {
+						return nil, 0, fmt.Errorf("cliopts: option -%c requires a value", r)
+					}
+					value = rawToken(tokens[i])
+				}
+				if err := SetValue(bf.FieldInfo, value); err != nil {
+					return nil, 0, err
+				}
+				bf.set = true
+			}
+			continue
+		}
+		if f == nil {
+			prefix := "--"
+			if tok.Kind == optstyle.Short {
+				prefix = "-"
+			}
+			return nil, 0, fmt.Errorf("cliopts: unknown option %s%s", prefix, tok.Name)
+		}
+
+		if f.Value.Kind() == reflect.Bool && !tok.HasValue {
+			f.Value.SetBool(true)
+			f.set = true
+			continue
+		}
+
+		value := tok.Value
+		if !tok.HasValue {
+			i++
+			if i >= len(tokens) {
+				return nil, 0, fmt.Errorf("cliopts: option --%s requires a value", tok.Name)
+			}
+			value = rawToken(tokens[i])
+		}
+		if err := SetValue(f.FieldInfo, value); err != nil {
+			return nil, 0, err
+		}
+		f.set = true
+	}
+
+	var missing []string
+	for _, f := range fields {
+		if f.set {
+			// Already given a value, either on the command line above or by
+			// a caller (e.g. ini.Load) before ParseArgs ran. Leave it alone
+			// so "ini first, flags override" holds even when the explicit
+			// value happens to equal the zero value.
+			continue
+		}
+		if f.Default != "" {
+			if err := SetValue(f.FieldInfo, f.Default); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+		if f.Required {
+			missing = append(missing, f.Long)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, 0, fmt.Errorf("cliopts: missing required option(s): --%s", strings.Join(missing, ", --"))
+	}
+	// Tokenize drops the first bare "--", so from that point on a token's
+	// index is one less than its index in args.
+	consumed = i
+	for j, arg := range args {
+		if arg == "--" {
+			if j <= i {
+				consumed = i + 1
+			}
+			break
+		}
+	}
+	return positional, consumed, nil
+}
+
+// rawToken reconstructs the literal text of a token consumed as a value,
+// e.g. when "-o" is immediately followed by another token that happens to
+// look like an option but is meant as the value.
+func rawToken(tok optstyle.Token) string {
+	switch tok.Kind {
+	case optstyle.Long:
+		return "--" + tok.Name
+	case optstyle.Short:
+		return "-" + tok.Name
+	default:
+		return tok.Name
+	}
+}
+
+// SetValue coerces raw into fi's underlying type (string, int, bool,
+// []string, or time.Duration) and assigns it. Exported so packages like ini
+// can reuse the same coercion rules cliopts applies to command-line values.
+func SetValue(fi FieldInfo, raw string) error {
+	switch fi.Value.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("cliopts: option --%s: %v", fi.Long, err)
+		}
+		fi.Value.SetInt(int64(d))
+		return nil
+	}
+
+	switch fi.Value.Kind() {
+	case reflect.String:
+		fi.Value.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("cliopts: option --%s: %v", fi.Long, err)
+		}
+		fi.Value.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("cliopts: option --%s: %v", fi.Long, err)
+		}
+		fi.Value.SetBool(b)
+	case reflect.Slice:
+		if fi.Value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("cliopts: option --%s: unsupported slice element type %s", fi.Long, fi.Value.Type().Elem())
+		}
+		fi.Value.Set(reflect.Append(fi.Value, reflect.ValueOf(raw)))
+	default:
+		return fmt.Errorf("cliopts: option --%s: unsupported field type %s", fi.Long, fi.Value.Kind())
+	}
+	return nil
+}
+
+// printHelp writes a --help listing of fields to stderr, grouped by their
+// "group" tag (fields without one are listed first, ungrouped).
+func printHelp(fields []*field) {
+	groups := map[string][]*field{}
+	var order []string
+	for _, f := range fields {
+		if _, ok := groups[f.Group]; !ok {
+			order = append(order, f.Group)
+		}
+		groups[f.Group] = append(groups[f.Group], f)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i] == "" })
+
+	for _, group := range order {
+		if group != "" {
+			fmt.Fprintf(os.Stderr, "\n%s:\n", group)
+		}
+		for _, f := range groups[group] {
+			name := "    "
+			if f.Short != "" {
+				name = fmt.Sprintf("-%s, ", f.Short)
+			}
+			if f.Long != "" {
+				name += fmt.Sprintf("--%s", f.Long)
+			}
+			fmt.Fprintf(os.Stderr, "  %-24s %s", name, f.Description)
+			if f.Default != "" {
+				fmt.Fprintf(os.Stderr, " (default: %s)", f.Default)
+			}
+			if f.Required {
+				fmt.Fprint(os.Stderr, " (required)")
+			}
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
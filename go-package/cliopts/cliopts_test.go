@@ -0,0 +1,134 @@
+package cliopts
+
+import (
+	"reflect"
+	"testing"
+)
+
+type bundleOpts struct {
+	Verbose bool   `short:"v" long:"verbose"`
+	All     bool   `short:"a" long:"all"`
+	File    string `short:"f" long:"file"`
+}
+
+func TestParseArgsBundledShortOptions(t *testing.T) {
+	var opts bundleOpts
+	if _, err := ParseArgs([]string{"-xvf", "out.txt"}, &opts); err == nil {
+		t.Fatalf("expected an error for unknown short option -x in the bundle, got none")
+	}
+
+	opts = bundleOpts{}
+	if _, err := ParseArgs([]string{"-avf", "out.txt"}, &opts); err != nil {
+		t.Fatalf("ParseArgs(-avf out.txt) = %v, want nil", err)
+	}
+	if !opts.All || !opts.Verbose || opts.File != "out.txt" {
+		t.Fatalf("got %+v, want All=true Verbose=true File=out.txt", opts)
+	}
+}
+
+func TestParseArgsBundledShortRejectsNonBooleanBeforeLast(t *testing.T) {
+	var opts bundleOpts
+	// "f" takes a value and isn't last in the bundle, so this must fail
+	// rather than silently eating "avv" as its value.
+	if _, err := ParseArgs([]string{"-favv"}, &opts); err == nil {
+		t.Fatalf("expected an error bundling a value-taking option before the last letter, got none")
+	}
+}
+
+type defaultOpts struct {
+	Count int    `long:"count" default:"5"`
+	Name  string `long:"name" required:"yes"`
+}
+
+func TestParseArgsAppliesDefaultWhenUnset(t *testing.T) {
+	var opts defaultOpts
+	if _, err := ParseArgs([]string{"--name", "a"}, &opts); err != nil {
+		t.Fatalf("ParseArgs() = %v, want nil", err)
+	}
+	if opts.Count != 5 {
+		t.Fatalf("Count = %d, want 5 (the tag default)", opts.Count)
+	}
+}
+
+func TestParseArgsRequiredMissing(t *testing.T) {
+	var opts defaultOpts
+	if _, err := ParseArgs(nil, &opts); err == nil {
+		t.Fatalf("expected an error for missing required --name, got none")
+	}
+}
+
+func TestStateSetThenParseArgsOverride(t *testing.T) {
+	var opts defaultOpts
+	state, err := NewState(&opts)
+	if err != nil {
+		t.Fatalf("NewState() = %v, want nil", err)
+	}
+
+	// Simulate an ini file setting Count to its zero value explicitly, and
+	// Name to a non-zero value.
+	if err := state.Set("count", "0"); err != nil {
+		t.Fatalf("Set(count) = %v, want nil", err)
+	}
+	if err := state.Set("name", "from-ini"); err != nil {
+		t.Fatalf("Set(name) = %v, want nil", err)
+	}
+
+	// A later ParseArgs with no matching flags must not reapply the tag
+	// default over the explicitly-set zero value, and must not complain
+	// that --name is missing.
+	if _, err := state.ParseArgs(nil); err != nil {
+		t.Fatalf("ParseArgs() = %v, want nil", err)
+	}
+	if opts.Count != 0 {
+		t.Fatalf("Count = %d, want 0 (explicitly set, should survive default fallback)", opts.Count)
+	}
+	if opts.Name != "from-ini" {
+		t.Fatalf("Name = %q, want %q", opts.Name, "from-ini")
+	}
+
+	// The command line should still be able to override an explicitly-set
+	// value.
+	if _, err := state.ParseArgs([]string{"--count", "9"}); err != nil {
+		t.Fatalf("ParseArgs(--count 9) = %v, want nil", err)
+	}
+	if opts.Count != 9 {
+		t.Fatalf("Count = %d, want 9 (overridden on the command line)", opts.Count)
+	}
+}
+
+type globalOpts struct {
+	Count int `short:"c" long:"count" default:"1"`
+}
+
+func TestParseLeadingStopsAtFirstPositionalAndSkipsValues(t *testing.T) {
+	var opts globalOpts
+	consumed, err := ParseLeading([]string{"--count", "5", "foo", "--name", "bob"}, &opts)
+	if err != nil {
+		t.Fatalf("ParseLeading() = %v, want nil", err)
+	}
+	if consumed != 2 {
+		t.Fatalf("consumed = %d, want 2 (the --count and its value)", consumed)
+	}
+	if opts.Count != 5 {
+		t.Fatalf("Count = %d, want 5", opts.Count)
+	}
+}
+
+func TestSetValueSlice(t *testing.T) {
+	type sliceOpts struct {
+		Tags []string `long:"tag"`
+	}
+	var opts sliceOpts
+	fields, err := Fields(&opts)
+	if err != nil {
+		t.Fatalf("Fields() = %v, want nil", err)
+	}
+	for _, raw := range []string{"a", "b"} {
+		if err := SetValue(fields[0], raw); err != nil {
+			t.Fatalf("SetValue(%q) = %v, want nil", raw, err)
+		}
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(opts.Tags, want) {
+		t.Fatalf("Tags = %v, want %v", opts.Tags, want)
+	}
+}
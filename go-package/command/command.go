@@ -0,0 +1,257 @@
+// Package command implements subcommand dispatch on top of cliopts: a
+// program registers subcommands with AddCommand, each carrying its own
+// tagged options struct, and Run parses the global options, matches the
+// next positional argument against a registered command (suggesting the
+// closest name on a miss), parses the remaining arguments into that
+// command's options struct, and invokes its Execute method.
+package command
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/jilpoom/go-tutorials/go-package/cliopts"
+	"github.com/jilpoom/go-tutorials/go-package/optstyle"
+)
+
+// Executor is implemented by a command's options struct to run the command
+// once its options have been parsed.
+type Executor interface {
+	Execute(args []string) error
+}
+
+// Command is a single registered subcommand, possibly with its own nested
+// subcommands.
+type Command struct {
+	Name  string
+	Short string
+	Long  string
+	Opts  Executor
+
+	parent   *Command
+	children map[string]*Command
+	order    []string
+}
+
+var root = &Command{children: map[string]*Command{}}
+
+// AddCommand registers a top-level subcommand named name. opts must
+// implement Executor and may be a tagged struct parsed the same way global
+// options are.
+func AddCommand(name, short, long string, opts Executor) *Command {
+	return root.AddCommand(name, short, long, opts)
+}
+
+// AddCommand registers name as a subcommand nested under c, allowing
+// arbitrarily deep command trees (e.g. "app foo bar --option=baz").
+func (c *Command) AddCommand(name, short, long string, opts Executor) *Command {
+	if c.children == nil {
+		c.children = map[string]*Command{}
+	}
+	child := &Command{Name: name, Short: short, Long: long, Opts: opts, parent: c}
+	c.children[name] = child
+	c.order = append(c.order, name)
+	return child
+}
+
+// Run parses globalOpts from args up to the first positional argument,
+// treats that argument as a (possibly nested) command path, parses the
+// remainder into the matched command's options struct, and calls Execute.
+func Run(args []string, globalOpts interface{}) error {
+	i := 0
+	if globalOpts != nil {
+		n, err := cliopts.ParseLeading(args, globalOpts)
+		if err != nil {
+			return err
+		}
+		i = n
+	} else {
+		// No global options struct to consult, so we can't tell a value
+		// token from a positional; fall back to treating anything
+		// option-shaped as consumed.
+		for i < len(args) {
+			if _, _, ok := optstyle.StripOptionPrefix(args[i]); !ok {
+				break
+			}
+			i++
+		}
+	}
+	if i >= len(args) {
+		return fmt.Errorf("command: no subcommand given, see --help")
+	}
+
+	cmd := root
+	rest := args[i:]
+	for len(rest) > 0 {
+		name := rest[0]
+		if name == "help" && len(rest) > 1 {
+			return printHelp(cmd, rest[1:])
+		}
+		next, ok := cmd.children[name]
+		if !ok {
+			return fmt.Errorf("command: unknown command %q%s", name, suggest(name, cmd))
+		}
+		cmd, rest = next, rest[1:]
+		if len(cmd.children) == 0 {
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		if _, _, ok := optstyle.StripOptionPrefix(rest[0]); ok {
+			break
+		}
+	}
+
+	if cmd.Opts == nil {
+		return fmt.Errorf("command: %q has no action, see --help", cmd.Name)
+	}
+	positional, err := cliopts.ParseArgs(rest, cmd.Opts)
+	if err != nil {
+		return err
+	}
+	return cmd.Opts.Execute(positional)
+}
+
+func printHelp(cmd *Command, path []string) error {
+	cur := cmd
+	for _, name := range path {
+		next, ok := cur.children[name]
+		if !ok {
+			return fmt.Errorf("command: unknown command %q%s", name, suggest(name, cur))
+		}
+		cur = next
+	}
+	fmt.Println(cur.Long)
+	if len(cur.order) > 0 {
+		fmt.Println("\nSubcommands:")
+		for _, name := range cur.order {
+			fmt.Printf("  %-16s %s\n", name, cur.children[name].Short)
+		}
+	}
+	return nil
+}
+
+// suggest returns a ` (did you mean "X"?)` hint for the registered child of
+// cmd whose name is closest (by Levenshtein distance) to name, or "" if no
+// candidate is close enough to be useful.
+func suggest(name string, cmd *Command) string {
+	best, bestDist := "", -1
+	for _, candidate := range cmd.order {
+		d := levenshtein(name, candidate)
+		if bestDist == -1 || d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	if best == "" || bestDist > len(name)/2+1 {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+func levenshtein(a, b string) int {
+	if a == b {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
+
+// Names returns the registered top-level command names, sorted, mainly
+// useful for generating help or shell completion.
+func Names() []string {
+	names := append([]string(nil), root.order...)
+	sort.Strings(names)
+	return names
+}
+
+// Exit prints err to stderr and exits with status 1, the same convention
+// main() is expected to follow when Run returns an error.
+func Exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// Resolve walks words the same way Run dispatches them, descending into the
+// registered command tree one matched name at a time, and returns the
+// deepest command reached. At each level it skips that level's leading
+// options (globalOpts at the root, then each matched command's own Opts),
+// using cliopts.ParseLeading on a scratch clone so the struct a real Run
+// would mutate is left untouched. It never errors: an unmatched, empty, or
+// malformed word just stops the walk and returns whatever was matched so
+// far, which is what shell completion wants.
+func Resolve(words []string, globalOpts interface{}) *Command {
+	cmd := root
+	opts := globalOpts
+	i := 0
+	for {
+		if opts != nil {
+			if n, err := cliopts.ParseLeading(words[i:], clonePtr(opts)); err == nil {
+				i += n
+			}
+		} else {
+			for i < len(words) {
+				if _, _, ok := optstyle.StripOptionPrefix(words[i]); !ok {
+					break
+				}
+				i++
+			}
+		}
+		if i >= len(words) {
+			return cmd
+		}
+		next, ok := cmd.children[words[i]]
+		if !ok {
+			return cmd
+		}
+		cmd, opts = next, next.Opts
+		i++
+	}
+}
+
+// clonePtr returns a fresh zero-value pointer of v's concrete pointer type,
+// so callers like Resolve can run cliopts.ParseLeading against it purely to
+// count consumed tokens without mutating the live options struct.
+func clonePtr(v interface{}) interface{} {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return v
+	}
+	return reflect.New(t.Elem()).Interface()
+}
+
+// ChildNames returns the names of c's immediate subcommands, sorted.
+func (c *Command) ChildNames() []string {
+	names := append([]string(nil), c.order...)
+	sort.Strings(names)
+	return names
+}
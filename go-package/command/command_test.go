@@ -0,0 +1,61 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+type testGlobalOpts struct {
+	Count int `short:"c" long:"count" default:"1"`
+}
+
+type recordingCmd struct {
+	Name string `long:"name"`
+	got  []string
+}
+
+func (c *recordingCmd) Execute(args []string) error {
+	c.got = args
+	return nil
+}
+
+func TestRunSeparateTokenGlobalValueYieldsToSubcommand(t *testing.T) {
+	// Reset the package-level command tree so tests don't see commands
+	// registered by other tests in this package.
+	orig := root
+	defer func() { root = orig }()
+	root = &Command{children: map[string]*Command{}}
+
+	foo := &recordingCmd{}
+	AddCommand("foo", "", "", foo)
+
+	var g testGlobalOpts
+	if err := Run([]string{"--count", "5", "foo", "--name", "bob", "extra"}, &g); err != nil {
+		t.Fatalf("Run() = %v, want nil", err)
+	}
+	if g.Count != 5 {
+		t.Fatalf("Count = %d, want 5 (global option with a separate-token value)", g.Count)
+	}
+	if foo.Name != "bob" {
+		t.Fatalf("foo.Name = %q, want %q", foo.Name, "bob")
+	}
+	if len(foo.got) != 1 || foo.got[0] != "extra" {
+		t.Fatalf("foo.got = %v, want [extra]", foo.got)
+	}
+}
+
+func TestRunUnknownCommandSuggestsClosestName(t *testing.T) {
+	orig := root
+	defer func() { root = orig }()
+	root = &Command{children: map[string]*Command{}}
+
+	AddCommand("status", "", "", &recordingCmd{})
+
+	err := Run([]string{"statuz"}, nil)
+	if err == nil {
+		t.Fatalf("Run() = nil, want an unknown-command error")
+	}
+	if want := `did you mean "status"?`; !strings.Contains(err.Error(), want) {
+		t.Fatalf("Run() error = %q, want it to contain %q", err, want)
+	}
+}
@@ -0,0 +1,156 @@
+// Package completion generates shell completion candidates from the same
+// command registry and cliopts tags the program already uses to parse its
+// arguments. A program opts in by calling Handle early in main, before its
+// own argument parsing:
+//
+//	if completion.Handle(os.Args[1:], &globalOpts) {
+//		return
+//	}
+//
+// Handle recognizes two invocation styles: a hidden "--__complete <words...>"
+// flag, which the glue scripts Bash, Zsh, and Fish below use, and the
+// COMP_LINE environment variable bash sets when it runs a completion command
+// registered with "complete -C".
+package completion
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jilpoom/go-tutorials/go-package/cliopts"
+	"github.com/jilpoom/go-tutorials/go-package/command"
+	"github.com/jilpoom/go-tutorials/go-package/optstyle"
+)
+
+// Completer lets an option field supply its own value completions, e.g. a
+// field holding a hostname could list known hosts.
+type Completer interface {
+	Complete(match string) []string
+}
+
+// Handle checks args for a completion request; if one is found it prints
+// newline-separated candidates to stdout and returns true, so the caller
+// can return from main immediately. globalOpts is the same struct passed
+// to command.Run, so completion can skip over its options (including ones
+// taking a separate-token value) the same way Run's dispatch does.
+func Handle(args []string, globalOpts interface{}) bool {
+	words, ok := completionWords(args)
+	if !ok {
+		return false
+	}
+	for _, c := range Complete(words, globalOpts) {
+		fmt.Println(c)
+	}
+	return true
+}
+
+func completionWords(args []string) ([]string, bool) {
+	if len(args) > 0 && args[0] == "--__complete" {
+		return args[1:], true
+	}
+	if line := os.Getenv("COMP_LINE"); line != "" {
+		words := strings.Fields(line)
+		if len(words) > 0 {
+			words = words[1:] // drop the program name
+		}
+		if strings.HasSuffix(line, " ") {
+			words = append(words, "")
+		}
+		return words, true
+	}
+	return nil, false
+}
+
+// Complete computes completion candidates for words, the command-line words
+// typed so far with the last entry being the (possibly empty) word still
+// being typed. globalOpts is threaded through to command.Resolve so it can
+// correctly skip a global option's value when descending the command tree.
+func Complete(words []string, globalOpts interface{}) []string {
+	cur := ""
+	rest := words
+	if len(words) > 0 {
+		cur = words[len(words)-1]
+		rest = words[:len(words)-1]
+	}
+
+	cmd := command.Resolve(rest, globalOpts)
+	var fields []cliopts.FieldInfo
+	if cmd.Opts != nil {
+		fields, _ = cliopts.Fields(cmd.Opts)
+	}
+
+	if name, kind, ok := optstyle.StripOptionPrefix(cur); ok {
+		switch kind {
+		case optstyle.Long:
+			return filterPrefix(longNames(fields), "--"+name)
+		case optstyle.Short:
+			return filterPrefix(shortNames(fields), "-"+name)
+		}
+	}
+
+	if len(rest) > 0 {
+		if f, ok := fieldForOption(fields, rest[len(rest)-1]); ok {
+			if candidates, ok := complete(f, cur); ok {
+				return candidates
+			}
+		}
+	}
+	return filterPrefix(cmd.ChildNames(), cur)
+}
+
+func complete(f cliopts.FieldInfo, match string) ([]string, bool) {
+	if c, ok := f.Value.Interface().(Completer); ok {
+		return c.Complete(match), true
+	}
+	if f.Value.CanAddr() {
+		if c, ok := f.Value.Addr().Interface().(Completer); ok {
+			return c.Complete(match), true
+		}
+	}
+	return nil, false
+}
+
+func fieldForOption(fields []cliopts.FieldInfo, word string) (cliopts.FieldInfo, bool) {
+	name, kind, ok := optstyle.StripOptionPrefix(word)
+	if !ok {
+		return cliopts.FieldInfo{}, false
+	}
+	name, _, _ = optstyle.SplitOption(name) // drop any inline "=value"/":value"
+	for _, f := range fields {
+		if (kind == optstyle.Long && f.Long == name) || (kind == optstyle.Short && f.Short == name) {
+			return f, true
+		}
+	}
+	return cliopts.FieldInfo{}, false
+}
+
+func longNames(fields []cliopts.FieldInfo) []string {
+	var names []string
+	for _, f := range fields {
+		if f.Long != "" {
+			names = append(names, "--"+f.Long)
+		}
+	}
+	return names
+}
+
+func shortNames(fields []cliopts.FieldInfo) []string {
+	var names []string
+	for _, f := range fields {
+		if f.Short != "" {
+			names = append(names, "-"+f.Short)
+		}
+	}
+	return names
+}
+
+func filterPrefix(candidates []string, prefix string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jilpoom/go-tutorials/go-package/command"
+)
+
+type completionTestOpts struct {
+	Verbose bool   `short:"v" long:"verbose"`
+	Host    string `short:"H" long:"host"`
+	Env     envOpt `long:"env"`
+}
+
+func (*completionTestOpts) Execute(args []string) error { return nil }
+
+type envOpt string
+
+func (envOpt) Complete(match string) []string {
+	candidates := []string{"dev", "staging", "prod"}
+	var out []string
+	for _, c := range candidates {
+		if len(match) <= len(c) && c[:len(match)] == match {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+type noopExecutor struct{}
+
+func (noopExecutor) Execute(args []string) error { return nil }
+
+func TestCompleteLongOptionPrefix(t *testing.T) {
+	command.AddCommand("completion-test-long", "", "", &completionTestOpts{})
+
+	got := Complete([]string{"completion-test-long", "--ho"}, nil)
+	want := []string{"--host"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Complete() = %v, want %v", got, want)
+	}
+}
+
+func TestCompleteShortOptionPrefix(t *testing.T) {
+	command.AddCommand("completion-test-short", "", "", &completionTestOpts{})
+
+	got := Complete([]string{"completion-test-short", "-v"}, nil)
+	want := []string{"-v"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Complete() = %v, want %v", got, want)
+	}
+}
+
+func TestCompleteValueViaCompleter(t *testing.T) {
+	command.AddCommand("completion-test-value", "", "", &completionTestOpts{})
+
+	got := Complete([]string{"completion-test-value", "--env", "st"}, nil)
+	want := []string{"staging"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Complete() = %v, want %v", got, want)
+	}
+}
+
+func TestCompleteFallsBackToChildNames(t *testing.T) {
+	parent := command.AddCommand("completion-test-parent", "", "", nil)
+	parent.AddCommand("foo", "", "", noopExecutor{})
+	parent.AddCommand("bar", "", "", noopExecutor{})
+
+	got := Complete([]string{"completion-test-parent", "f"}, nil)
+	want := []string{"foo"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Complete() = %v, want %v", got, want)
+	}
+}
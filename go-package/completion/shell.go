@@ -0,0 +1,35 @@
+package completion
+
+import "fmt"
+
+// Bash returns a bash script that, once eval'd (e.g. from .bashrc, with
+// prog printing this string), wires prog up to complete using its own
+// "--__complete" flag.
+func Bash(prog string) string {
+	return fmt.Sprintf(`_%[1]s_complete() {
+  COMPREPLY=( $(%[1]s --__complete "${COMP_WORDS[@]:1:$COMP_CWORD}") )
+}
+complete -F _%[1]s_complete %[1]s
+`, prog)
+}
+
+// Zsh returns a zsh script equivalent of Bash, using zsh's bashcompinit
+// compatibility layer so the same "--__complete" flag is reused.
+func Zsh(prog string) string {
+	return fmt.Sprintf(`autoload -U +X bashcompinit && bashcompinit
+_%[1]s_complete() {
+  COMPREPLY=( $(%[1]s --__complete "${words[@]:1}") )
+}
+complete -F _%[1]s_complete %[1]s
+`, prog)
+}
+
+// Fish returns a fish completion script driving the same "--__complete"
+// flag.
+func Fish(prog string) string {
+	return fmt.Sprintf(`function __%[1]s_complete
+    %[1]s --__complete (commandline -opc)[2..-1] (commandline -ct)
+end
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`, prog)
+}
@@ -1,15 +1,21 @@
 package main
 
 import (
-	"flag"
 	"fmt"
-)
+	"log"
 
-func main() {
-	option := flag.String("option", "default", "option description")
+	"github.com/jilpoom/go-tutorials/go-package/cliopts"
+)
 
-	flag.Parse()
+type Options struct {
+	Option string `short:"o" long:"option" default:"default" description:"option description"`
+}
 
-	fmt.Println("option:", *option)
+func main() {
+	var opts Options
+	if err := cliopts.Parse(&opts); err != nil {
+		log.Fatal(err)
+	}
 
-}
\ No newline at end of file
+	fmt.Println("option:", opts.Option)
+}
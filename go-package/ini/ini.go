@@ -0,0 +1,156 @@
+// Package ini loads and writes INI config files that mirror a cliopts
+// tagged options struct: section names correspond to a field's "group" tag
+// (ungrouped fields live at the top of the file, before any section
+// header) and keys correspond to its "long" tag. Load a file into a
+// *cliopts.State before parsing the command line into the same State, so
+// command-line flags take precedence over the file and the file's values
+// take precedence over tag defaults:
+//
+//	var opts Options
+//	state, err := cliopts.NewState(&opts)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := ini.Load("myapp.ini", state); err != nil {
+//		log.Fatal(err)
+//	}
+//	if _, err := state.ParseArgs(os.Args[1:]); err != nil {
+//		log.Fatal(err)
+//	}
+package ini
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jilpoom/go-tutorials/go-package/cliopts"
+)
+
+// ParseError reports a problem at a specific file and line, such as an
+// unrecognized key.
+type ParseError struct {
+	File string
+	Line int
+	Key  string
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// Load reads path and applies its values onto state's fields via
+// state.Set, which marks each one explicitly set so a later
+// state.ParseArgs neither reapplies the field's tag default over it nor
+// reports it missing, even if the file set it to the zero value (e.g.
+// `count = 0`). Keys not present in the file are left untouched, to be
+// filled in later by ParseArgs's "default"/"required" handling. An
+// unrecognized section or key produces a *ParseError identifying the
+// offending line.
+func Load(path string, state *cliopts.State) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	bySection := map[string]map[string]cliopts.FieldInfo{}
+	for _, f := range state.Fields() {
+		m := bySection[f.Group]
+		if m == nil {
+			m = map[string]cliopts.FieldInfo{}
+			bySection[f.Group] = m
+		}
+		m[f.Long] = f
+	}
+
+	section := ""
+	for lineNo, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := bySection[section]; !ok {
+				return &ParseError{File: path, Line: lineNo + 1, Msg: fmt.Sprintf("unknown section %q", section)}
+			}
+			continue
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return &ParseError{File: path, Line: lineNo + 1, Msg: fmt.Sprintf("expected \"key = value\", got %q", line)}
+		}
+		key := strings.TrimSpace(line[:eq])
+		value := strings.TrimSpace(line[eq+1:])
+
+		fieldsInSection, ok := bySection[section]
+		if !ok {
+			return &ParseError{File: path, Line: lineNo + 1, Key: key, Msg: fmt.Sprintf("unknown section %q", section)}
+		}
+		if _, ok := fieldsInSection[key]; !ok {
+			return &ParseError{File: path, Line: lineNo + 1, Key: key, Msg: fmt.Sprintf("unknown key %q in section %q", key, section)}
+		}
+		if err := state.Set(key, value); err != nil {
+			return &ParseError{File: path, Line: lineNo + 1, Key: key, Msg: err.Error()}
+		}
+	}
+	return nil
+}
+
+// WriteIni serializes v's current option values to w in INI form, grouped
+// the same way Load reads them. A field still at its tag default is written
+// out commented, so the file documents every available key without forcing
+// the user to override all of them.
+func WriteIni(w io.Writer, v interface{}) error {
+	fields, err := cliopts.Fields(v)
+	if err != nil {
+		return err
+	}
+
+	groups := map[string][]cliopts.FieldInfo{}
+	var order []string
+	for _, f := range fields {
+		if _, ok := groups[f.Group]; !ok {
+			order = append(order, f.Group)
+		}
+		groups[f.Group] = append(groups[f.Group], f)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i] == "" && order[j] != "" })
+
+	for _, group := range order {
+		if group != "" {
+			fmt.Fprintf(w, "[%s]\n", group)
+		}
+		for _, f := range groups[group] {
+			value := formatValue(f)
+			if value == f.Default {
+				fmt.Fprintf(w, "; %s = %s\n", f.Long, f.Default)
+			} else {
+				fmt.Fprintf(w, "%s = %s\n", f.Long, value)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func formatValue(f cliopts.FieldInfo) string {
+	if d, ok := f.Value.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	if f.Value.Kind() == reflect.Slice {
+		n := f.Value.Len()
+		parts := make([]string, n)
+		for i := 0; i < n; i++ {
+			parts[i] = fmt.Sprintf("%v", f.Value.Index(i).Interface())
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprintf("%v", f.Value.Interface())
+}
@@ -0,0 +1,97 @@
+package ini
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jilpoom/go-tutorials/go-package/cliopts"
+)
+
+type testOpts struct {
+	Count int    `long:"count" default:"5"`
+	Name  string `long:"name" required:"yes"`
+	Host  string `long:"host" group:"server" default:"localhost"`
+}
+
+func writeIni(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.ini")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("WriteFile(%s) = %v, want nil", path, err)
+	}
+	return path
+}
+
+func TestLoadThenParseArgsOverrideContract(t *testing.T) {
+	path := writeIni(t, "count = 0\nname = from-ini\n\n[server]\nhost = example.com\n")
+
+	var opts testOpts
+	state, err := cliopts.NewState(&opts)
+	if err != nil {
+		t.Fatalf("NewState() = %v, want nil", err)
+	}
+	if err := Load(path, state); err != nil {
+		t.Fatalf("Load() = %v, want nil", err)
+	}
+
+	// A field explicitly set to its zero value by the file must survive
+	// ParseArgs's default/required handling: it's not "unset" just because
+	// it's zero.
+	if _, err := state.ParseArgs(nil); err != nil {
+		t.Fatalf("ParseArgs() = %v, want nil", err)
+	}
+	if opts.Count != 0 {
+		t.Fatalf("Count = %d, want 0 (set by ini, must not be overwritten by the tag default)", opts.Count)
+	}
+	if opts.Name != "from-ini" {
+		t.Fatalf("Name = %q, want %q", opts.Name, "from-ini")
+	}
+	if opts.Host != "example.com" {
+		t.Fatalf("Host = %q, want %q", opts.Host, "example.com")
+	}
+
+	// The command line still overrides a value the file set.
+	if _, err := state.ParseArgs([]string{"--count", "9"}); err != nil {
+		t.Fatalf("ParseArgs(--count 9) = %v, want nil", err)
+	}
+	if opts.Count != 9 {
+		t.Fatalf("Count = %d, want 9 (overridden on the command line)", opts.Count)
+	}
+}
+
+func TestLoadLeavesUnsetKeysForArgsDefaults(t *testing.T) {
+	path := writeIni(t, "name = from-ini\n")
+
+	var opts testOpts
+	state, err := cliopts.NewState(&opts)
+	if err != nil {
+		t.Fatalf("NewState() = %v, want nil", err)
+	}
+	if err := Load(path, state); err != nil {
+		t.Fatalf("Load() = %v, want nil", err)
+	}
+	if _, err := state.ParseArgs(nil); err != nil {
+		t.Fatalf("ParseArgs() = %v, want nil", err)
+	}
+	if opts.Count != 5 {
+		t.Fatalf("Count = %d, want 5 (tag default, since ini never set it)", opts.Count)
+	}
+	if opts.Host != "localhost" {
+		t.Fatalf("Host = %q, want %q (tag default, since ini never set it)", opts.Host, "localhost")
+	}
+}
+
+func TestLoadUnknownKeyIsParseError(t *testing.T) {
+	path := writeIni(t, "bogus = 1\n")
+
+	var opts testOpts
+	state, err := cliopts.NewState(&opts)
+	if err != nil {
+		t.Fatalf("NewState() = %v, want nil", err)
+	}
+	err = Load(path, state)
+	if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("Load() = %v (%T), want a *ParseError", err, err)
+	}
+}
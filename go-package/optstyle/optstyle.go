@@ -0,0 +1,56 @@
+// Package optstyle tokenizes command-line arguments into options and
+// positionals without committing to a particular option syntax: the
+// platform-specific pieces (which prefix marks an option, which character
+// separates a name from its inline value) live in optstyle_other.go and
+// optstyle_windows.go behind a build tag, so callers like cliopts only ever
+// deal with the style-agnostic Token stream produced here.
+package optstyle
+
+// Kind classifies a Token.
+type Kind int
+
+const (
+	// Positional marks a plain argument, including everything after a "--"
+	// end-of-options sentinel.
+	Positional Kind = iota
+	// Long marks a long-form option such as "--option" or "/option" on
+	// Windows.
+	Long
+	// Short marks a short-form option such as "-o", possibly a bundle of
+	// several short names like "-xvf".
+	Short
+)
+
+// Token is one parsed command-line argument.
+type Token struct {
+	Kind     Kind
+	Name     string // option name (without prefix), or the raw arg for Positional
+	Value    string // inline value, e.g. the "bar" in "--foo=bar"
+	HasValue bool
+}
+
+// Tokenize splits args into a stream of Tokens, honoring the "--"
+// end-of-options sentinel: every argument after the first bare "--" is
+// returned as Positional, prefix and all.
+func Tokenize(args []string) []Token {
+	tokens := make([]Token, 0, len(args))
+	positionalOnly := false
+	for _, arg := range args {
+		if !positionalOnly && arg == "--" {
+			positionalOnly = true
+			continue
+		}
+		if positionalOnly {
+			tokens = append(tokens, Token{Kind: Positional, Name: arg})
+			continue
+		}
+		name, kind, ok := StripOptionPrefix(arg)
+		if !ok {
+			tokens = append(tokens, Token{Kind: Positional, Name: arg})
+			continue
+		}
+		key, value, hasValue := SplitOption(name)
+		tokens = append(tokens, Token{Kind: kind, Name: key, Value: value, HasValue: hasValue})
+	}
+	return tokens
+}
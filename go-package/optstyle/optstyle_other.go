@@ -0,0 +1,25 @@
+//go:build !windows
+
+package optstyle
+
+import "strings"
+
+// StripOptionPrefix recognizes the POSIX/GNU "-o" / "--option" prefixes.
+func StripOptionPrefix(arg string) (name string, kind Kind, ok bool) {
+	switch {
+	case strings.HasPrefix(arg, "--") && len(arg) > 2:
+		return arg[2:], Long, true
+	case strings.HasPrefix(arg, "-") && len(arg) > 1:
+		return arg[1:], Short, true
+	default:
+		return "", Positional, false
+	}
+}
+
+// SplitOption splits a POSIX "name=value" option body on "=".
+func SplitOption(s string) (name, value string, hasValue bool) {
+	if i := strings.IndexByte(s, '='); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+	return s, "", false
+}
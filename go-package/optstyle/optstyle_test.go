@@ -0,0 +1,60 @@
+package optstyle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStripOptionPrefix(t *testing.T) {
+	cases := []struct {
+		arg      string
+		wantName string
+		wantKind Kind
+		wantOk   bool
+	}{
+		{"--option", "option", Long, true},
+		{"-o", "o", Short, true},
+		{"-", "", Positional, false},
+		{"foo", "", Positional, false},
+	}
+	for _, c := range cases {
+		name, kind, ok := StripOptionPrefix(c.arg)
+		if name != c.wantName || kind != c.wantKind || ok != c.wantOk {
+			t.Errorf("StripOptionPrefix(%q) = (%q, %v, %v), want (%q, %v, %v)",
+				c.arg, name, kind, ok, c.wantName, c.wantKind, c.wantOk)
+		}
+	}
+}
+
+func TestSplitOption(t *testing.T) {
+	cases := []struct {
+		s         string
+		wantName  string
+		wantValue string
+		wantHas   bool
+	}{
+		{"option=value", "option", "value", true},
+		{"option", "option", "", false},
+		{"option=", "option", "", true},
+	}
+	for _, c := range cases {
+		name, value, has := SplitOption(c.s)
+		if name != c.wantName || value != c.wantValue || has != c.wantHas {
+			t.Errorf("SplitOption(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				c.s, name, value, has, c.wantName, c.wantValue, c.wantHas)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize([]string{"--name=bob", "-v", "foo", "--", "--not-an-option"})
+	want := []Token{
+		{Kind: Long, Name: "name", Value: "bob", HasValue: true},
+		{Kind: Short, Name: "v"},
+		{Kind: Positional, Name: "foo"},
+		{Kind: Positional, Name: "--not-an-option"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tokenize() = %#v, want %#v", got, want)
+	}
+}
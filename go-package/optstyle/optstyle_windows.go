@@ -0,0 +1,22 @@
+//go:build windows
+
+package optstyle
+
+import "strings"
+
+// StripOptionPrefix recognizes the Windows "/option" prefix. Windows style
+// has no separate short form, so every option is treated as Long.
+func StripOptionPrefix(arg string) (name string, kind Kind, ok bool) {
+	if strings.HasPrefix(arg, "/") && len(arg) > 1 {
+		return arg[1:], Long, true
+	}
+	return "", Positional, false
+}
+
+// SplitOption splits a Windows "name:value" option body on ":".
+func SplitOption(s string) (name, value string, hasValue bool) {
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+	return s, "", false
+}